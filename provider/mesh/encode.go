@@ -0,0 +1,154 @@
+package mesh
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/prometheus/alertmanager/provider/mesh/pb"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/log"
+	"github.com/prometheus/common/model"
+	"github.com/satori/go.uuid"
+)
+
+// mustTimestamp converts t to a google.protobuf.Timestamp. validateSilence
+// bounds StartsAt/EndsAt/UpdatedAt to the range ptypes.TimestampProto
+// accepts before a silence is ever merged or gossiped, so t is only ever
+// out of range here if that invariant has been broken elsewhere.
+func mustTimestamp(t time.Time) *timestamp.Timestamp {
+	ts, err := ptypes.TimestampProto(t)
+	if err != nil {
+		panic(err)
+	}
+	return ts
+}
+
+// timestampFromPB converts ts to a time.Time, reporting a nil or
+// out-of-range Timestamp as an error rather than panicking, so callers can
+// drop just the entry it belongs to instead of failing an entire decode.
+func timestampFromPB(ts *timestamp.Timestamp) (time.Time, error) {
+	if ts == nil {
+		return time.Time{}, errors.New("missing timestamp")
+	}
+	return ptypes.Timestamp(ts)
+}
+
+func notificationStateToPB(s *notificationState) *pb.NotificationSet {
+	set := &pb.NotificationSet{Entries: make([]*pb.NotificationEntry, 0, len(s.set))}
+	for _, e := range s.set {
+		set.Entries = append(set.Entries, &pb.NotificationEntry{
+			Alert:     uint64(e.Alert),
+			Receiver:  e.Receiver,
+			Resolved:  e.Resolved,
+			Timestamp: mustTimestamp(e.Timestamp),
+		})
+	}
+	return set
+}
+
+// notificationStateFromPB drops any entry with a missing or out-of-range
+// Timestamp instead of failing the whole decode, since set is typically a
+// peer's entire state and one bad entry (e.g. from a buggy older peer)
+// should not block every other entry from being merged.
+func notificationStateFromPB(set *pb.NotificationSet, logger log.Logger) (*notificationState, error) {
+	s := newNotificationState()
+	for _, e := range set.Entries {
+		ts, err := timestampFromPB(e.Timestamp)
+		if err != nil || !validTimestamp(ts) {
+			logger.With("alert", e.Alert).With("receiver", e.Receiver).With("err", err).
+				Warn("dropping notification entry received from peer")
+			continue
+		}
+		k := notificationKey(model.Fingerprint(e.Alert), e.Receiver)
+		s.set[k] = notificationEntry{
+			Alert:     model.Fingerprint(e.Alert),
+			Receiver:  e.Receiver,
+			Resolved:  e.Resolved,
+			Timestamp: ts,
+		}
+	}
+	return s, nil
+}
+
+func silenceStateToPB(s *silenceState) *pb.SilenceSet {
+	set := &pb.SilenceSet{Silences: make([]*pb.Silence, 0, len(s.set))}
+	for _, sil := range s.set {
+		set.Silences = append(set.Silences, silenceToPB(sil))
+	}
+	return set
+}
+
+// silenceStateFromPB drops any silence that fails to decode (e.g. a missing
+// or out-of-range timestamp) instead of failing the whole decode, since set
+// is typically a peer's entire state and one bad entry should not block
+// every other silence from being merged.
+func silenceStateFromPB(set *pb.SilenceSet, logger log.Logger) (*silenceState, error) {
+	s := newSilenceState()
+	for _, psil := range set.Silences {
+		sil, err := silenceFromPB(psil)
+		if err != nil {
+			logger.With("err", err).Warn("dropping silence received from peer")
+			continue
+		}
+		s.set[sil.ID] = sil
+	}
+	return s, nil
+}
+
+func silenceToPB(sil *types.Silence) *pb.Silence {
+	matchers := make([]*pb.Matcher, 0, len(sil.Matchers))
+	for _, m := range sil.Matchers {
+		matchers = append(matchers, &pb.Matcher{
+			Name:    m.Name,
+			Value:   m.Value,
+			IsRegex: m.IsRegex,
+		})
+	}
+	return &pb.Silence{
+		Id:        sil.ID.Bytes(),
+		Matchers:  matchers,
+		StartsAt:  mustTimestamp(sil.StartsAt),
+		EndsAt:    mustTimestamp(sil.EndsAt),
+		UpdatedAt: mustTimestamp(sil.UpdatedAt),
+		CreatedBy: sil.CreatedBy,
+		Comment:   sil.Comment,
+	}
+}
+
+func silenceFromPB(psil *pb.Silence) (*types.Silence, error) {
+	id, err := uuid.FromBytes(psil.Id)
+	if err != nil {
+		return nil, err
+	}
+	startsAt, err := timestampFromPB(psil.StartsAt)
+	if err != nil {
+		return nil, err
+	}
+	endsAt, err := timestampFromPB(psil.EndsAt)
+	if err != nil {
+		return nil, err
+	}
+	updatedAt, err := timestampFromPB(psil.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	matchers := make([]*types.Matcher, 0, len(psil.Matchers))
+	for _, m := range psil.Matchers {
+		matchers = append(matchers, &types.Matcher{
+			Name:    m.Name,
+			Value:   m.Value,
+			IsRegex: m.IsRegex,
+		})
+	}
+	return &types.Silence{
+		ID:        id,
+		Matchers:  matchers,
+		StartsAt:  startsAt,
+		EndsAt:    endsAt,
+		UpdatedAt: updatedAt,
+		CreatedBy: psil.CreatedBy,
+		Comment:   psil.Comment,
+	}, nil
+}
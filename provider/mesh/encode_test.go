@@ -0,0 +1,117 @@
+package mesh
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/provider/mesh/pb"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/log"
+	"github.com/prometheus/common/model"
+	"github.com/satori/go.uuid"
+)
+
+func TestSilenceStateEncodeDecode(t *testing.T) {
+	now := time.Now().Round(time.Second)
+	st := newSilenceState()
+	id := uuid.NewV4()
+	st.set[id] = &types.Silence{
+		ID: id,
+		Matchers: []*types.Matcher{
+			{Name: "alertname", Value: "Test"},
+			{Name: "severity", Value: "crit.*", IsRegex: true},
+		},
+		StartsAt:  now,
+		EndsAt:    now.Add(time.Hour),
+		UpdatedAt: now,
+		CreatedBy: "me",
+		Comment:   "testing",
+	}
+
+	parts := st.Encode()
+	if len(parts) != 1 {
+		t.Fatalf("expected a single encoded part, got %d", len(parts))
+	}
+	got, err := decodeSilenceSet(parts[0], nil)
+	if err != nil {
+		t.Fatalf("decodeSilenceSet: %s", err)
+	}
+	if len(got.set) != len(st.set) {
+		t.Fatalf("expected %d silences, got %d", len(st.set), len(got.set))
+	}
+	for id, want := range st.set {
+		have, ok := got.set[id]
+		if !ok {
+			t.Fatalf("missing silence %s after round-trip", id)
+		}
+		if !have.UpdatedAt.Equal(want.UpdatedAt) || have.Comment != want.Comment || len(have.Matchers) != len(want.Matchers) {
+			t.Errorf("silence %s did not round-trip: got %+v, want %+v", id, have, want)
+		}
+	}
+}
+
+// TestSilenceStateDecodeDropsMissingTimestamp ensures that a single silence
+// with a missing timestamp (here StartsAt, e.g. from a peer running older, buggy
+// code) is dropped rather than failing the decode of the whole SilenceSet,
+// so the rest of a peer's state stays mergeable.
+func TestSilenceStateDecodeDropsMissingTimestamp(t *testing.T) {
+	now := time.Now().Round(time.Second)
+	good := uuid.NewV4()
+	bad := uuid.NewV4()
+	set := &pb.SilenceSet{
+		Silences: []*pb.Silence{
+			{
+				Id:        good.Bytes(),
+				Matchers:  []*pb.Matcher{{Name: "alertname", Value: "Test"}},
+				StartsAt:  mustTimestamp(now),
+				EndsAt:    mustTimestamp(now.Add(time.Hour)),
+				UpdatedAt: mustTimestamp(now),
+			},
+			{
+				Id:        bad.Bytes(),
+				Matchers:  []*pb.Matcher{{Name: "alertname", Value: "Test"}},
+				StartsAt:  nil,
+				EndsAt:    mustTimestamp(now.Add(time.Hour)),
+				UpdatedAt: mustTimestamp(now),
+			},
+		},
+	}
+	b, err := set.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal: %s", err)
+	}
+	got, err := decodeSilenceSet(b, log.Base())
+	if err != nil {
+		t.Fatalf("decodeSilenceSet: %s", err)
+	}
+	if _, ok := got.set[good]; !ok {
+		t.Error("expected the well-formed silence to survive the decode")
+	}
+	if _, ok := got.set[bad]; ok {
+		t.Error("expected the silence with a missing timestamp to be dropped")
+	}
+}
+
+func TestNotificationStateEncodeDecode(t *testing.T) {
+	now := time.Now().Round(time.Second)
+	st := newNotificationState()
+	st.set[notificationKey(model.Fingerprint(42), "default")] = notificationEntry{
+		Alert:     model.Fingerprint(42),
+		Receiver:  "default",
+		Resolved:  true,
+		Timestamp: now,
+	}
+
+	parts := st.Encode()
+	got, err := decodeNotificationSet(parts[0], nil)
+	if err != nil {
+		t.Fatalf("decodeNotificationSet: %s", err)
+	}
+	e, ok := got.set[notificationKey(model.Fingerprint(42), "default")]
+	if !ok {
+		t.Fatal("missing notification entry after round-trip")
+	}
+	if !e.Timestamp.Equal(now) || !e.Resolved || e.Receiver != "default" {
+		t.Errorf("entry did not round-trip: got %+v", e)
+	}
+}
@@ -0,0 +1,263 @@
+package mesh
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sync"
+
+	"github.com/hashicorp/memberlist"
+	"github.com/prometheus/common/log"
+)
+
+// memberlistTransport is the shared hub behind the memberlist.Memberlist
+// connection used by the memberlist cluster backend, an alternative to the
+// weaveworks/mesh backend for operators who cannot depend on
+// weaveworks/mesh. It uses memberlist's SWIM failure detector for membership
+// and a TransmitLimitedQueue for anti-entropy broadcast.
+//
+// Unlike weaveworks/mesh, a memberlist.Memberlist only supports a single
+// Delegate, so Silences and NotificationInfos cannot each open their own
+// connection the way they open separate mesh.Gossip channels. Instead they
+// each attach via Transport(topic) with a distinct topic ("silences",
+// "nflog"), and every frame that crosses the wire is tagged with its topic
+// so the shared Delegate can dispatch it to the right Gossiper.
+type memberlistTransport struct {
+	ml     *memberlist.Memberlist
+	queue  *memberlist.TransmitLimitedQueue
+	logger log.Logger
+
+	mtx  sync.Mutex
+	recv map[string]Gossiper
+}
+
+// NewMemberlistTransport creates a hub before any memberlist.Memberlist
+// exists. The caller is responsible for creating and joining the Memberlist
+// with the Delegate and EventDelegate returned below, and for handing it to
+// Init, before traffic is expected to flow.
+func NewMemberlistTransport(logger log.Logger) *memberlistTransport {
+	t := &memberlistTransport{logger: logger, recv: map[string]Gossiper{}}
+	t.queue = &memberlist.TransmitLimitedQueue{
+		NumNodes:       func() int { return t.numNodes() },
+		RetransmitMult: 3,
+	}
+	return t
+}
+
+// Init finishes wiring the transport up to a running Memberlist instance.
+// It must be called once the Memberlist has been created, since the queue's
+// NumNodes callback depends on it.
+func (t *memberlistTransport) Init(ml *memberlist.Memberlist) {
+	t.ml = ml
+}
+
+// Transport returns a Transport scoped to topic, multiplexed with every
+// other topic attached to t over the same underlying memberlist connection.
+// Silences and NotificationInfos each Register() their own, e.g.
+// t.Transport("silences") and t.Transport("nflog").
+func (t *memberlistTransport) Transport(topic string) Transport {
+	return &memberlistTopicTransport{t: t, topic: topic}
+}
+
+func (t *memberlistTransport) numNodes() int {
+	if t.ml == nil {
+		return 1
+	}
+	return t.ml.NumMembers()
+}
+
+func (t *memberlistTransport) registerReceiver(topic string, g Gossiper) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	t.recv[topic] = g
+}
+
+func (t *memberlistTransport) receiver(topic string) Gossiper {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	return t.recv[topic]
+}
+
+// receivers returns a snapshot of every currently registered topic, used to
+// build the combined full-state payload handed to LocalState.
+func (t *memberlistTransport) receivers() map[string]Gossiper {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	out := make(map[string]Gossiper, len(t.recv))
+	for k, v := range t.recv {
+		out[k] = v
+	}
+	return out
+}
+
+// Delegate returns the memberlist.Delegate that feeds incoming broadcasts and
+// unicasts to the registered Gossipers and supplies local state to peers
+// that join or periodically pull a full sync.
+func (t *memberlistTransport) Delegate() memberlist.Delegate {
+	return &delegate{t: t}
+}
+
+// EventDelegate returns the memberlist.EventDelegate that logs node join and
+// leave events so operators can observe peer churn.
+func (t *memberlistTransport) EventDelegate() memberlist.EventDelegate {
+	return &eventDelegate{logger: t.logger}
+}
+
+// memberlistTopicTransport is the Transport seen by a single Gossiper
+// (Silences or NotificationInfos). It tags every outgoing frame with topic
+// so the shared delegate can route it back to the matching Gossiper.
+type memberlistTopicTransport struct {
+	t     *memberlistTransport
+	topic string
+}
+
+func (m *memberlistTopicTransport) Broadcast(d GossipData) {
+	for _, b := range d.Encode() {
+		m.t.queue.QueueBroadcast(gossipBroadcast{msg: encodeFrame(m.topic, b)})
+	}
+}
+
+func (m *memberlistTopicTransport) Unicast(peer string, b []byte) error {
+	for _, n := range m.t.ml.Members() {
+		if n.Name == peer {
+			return m.t.ml.SendReliable(n, encodeFrame(m.topic, b))
+		}
+	}
+	return fmt.Errorf("memberlist: unknown peer %q", peer)
+}
+
+func (m *memberlistTopicTransport) RegisterReceiver(g Gossiper) {
+	m.t.registerReceiver(m.topic, g)
+}
+
+// gossipBroadcast implements memberlist.Broadcast for a single topic-tagged
+// frame.
+type gossipBroadcast struct {
+	msg []byte
+}
+
+func (b gossipBroadcast) Invalidates(other memberlist.Broadcast) bool { return false }
+func (b gossipBroadcast) Message() []byte                             { return b.msg }
+func (b gossipBroadcast) Finished()                                   {}
+
+// delegate feeds memberlist's user-message channel and push/pull full-state
+// sync into the Gossiper registered for each frame's topic.
+type delegate struct {
+	t *memberlistTransport
+}
+
+func (d *delegate) NodeMeta(limit int) []byte { return nil }
+
+func (d *delegate) NotifyMsg(b []byte) {
+	topic, payload, _, err := decodeFrame(b)
+	if err != nil {
+		d.t.logger.With("err", err).Warn("memberlist: dropping malformed broadcast")
+		return
+	}
+	g := d.t.receiver(topic)
+	if g == nil {
+		return
+	}
+	if _, err := g.OnGossipBroadcast("", payload); err != nil {
+		d.t.logger.With("err", err).Warn("memberlist: failed to merge broadcast")
+	}
+}
+
+func (d *delegate) GetBroadcasts(overhead, limit int) [][]byte {
+	return d.t.queue.GetBroadcasts(overhead, limit)
+}
+
+// LocalState concatenates a topic-tagged frame per registered Gossiper so a
+// single push/pull exchange carries both the silence and the
+// notification-info state.
+func (d *delegate) LocalState(join bool) []byte {
+	var out []byte
+	for topic, g := range d.t.receivers() {
+		parts := g.Gossip().Encode()
+		if len(parts) == 0 {
+			continue
+		}
+		out = append(out, encodeFrame(topic, parts[0])...)
+	}
+	return out
+}
+
+// MergeRemoteState splits buf back into its topic-tagged frames and merges
+// each into the Gossiper registered for that topic.
+func (d *delegate) MergeRemoteState(buf []byte, join bool) {
+	for len(buf) > 0 {
+		topic, payload, rest, err := decodeFrame(buf)
+		if err != nil {
+			d.t.logger.With("err", err).Warn("memberlist: dropping malformed full state")
+			return
+		}
+		buf = rest
+
+		g := d.t.receiver(topic)
+		if g == nil {
+			continue
+		}
+		if _, err := g.OnGossip(payload); err != nil {
+			d.t.logger.With("err", err).Warn("memberlist: failed to merge remote state")
+		}
+	}
+}
+
+// encodeFrame prefixes payload with its topic so the shared delegate can
+// dispatch it to the right Gossiper: a single length byte (topics are short,
+// fixed names) followed by the topic, then the payload length as a uvarint
+// and the payload itself.
+func encodeFrame(topic string, payload []byte) []byte {
+	lenBuf := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutUvarint(lenBuf, uint64(len(payload)))
+
+	out := make([]byte, 0, 1+len(topic)+n+len(payload))
+	out = append(out, byte(len(topic)))
+	out = append(out, topic...)
+	out = append(out, lenBuf[:n]...)
+	out = append(out, payload...)
+	return out
+}
+
+// decodeFrame reads a single topic-tagged frame off the front of b and
+// returns the remainder, if any, so callers can loop over several
+// concatenated frames.
+func decodeFrame(b []byte) (topic string, payload, rest []byte, err error) {
+	if len(b) < 1 {
+		return "", nil, nil, fmt.Errorf("memberlist: empty frame")
+	}
+	tl := int(b[0])
+	b = b[1:]
+	if len(b) < tl {
+		return "", nil, nil, fmt.Errorf("memberlist: truncated topic")
+	}
+	topic = string(b[:tl])
+	b = b[tl:]
+
+	pl, n := binary.Uvarint(b)
+	if n <= 0 {
+		return "", nil, nil, fmt.Errorf("memberlist: invalid payload length")
+	}
+	b = b[n:]
+	if uint64(len(b)) < pl {
+		return "", nil, nil, fmt.Errorf("memberlist: truncated payload")
+	}
+	return topic, b[:pl], b[pl:], nil
+}
+
+// eventDelegate logs node join/leave/update events for operational
+// visibility into peer churn.
+type eventDelegate struct {
+	logger log.Logger
+}
+
+func (e *eventDelegate) NotifyJoin(n *memberlist.Node) {
+	e.logger.With("peer", n.Name).With("addr", n.Address()).Info("memberlist: peer joined")
+}
+
+func (e *eventDelegate) NotifyLeave(n *memberlist.Node) {
+	e.logger.With("peer", n.Name).With("addr", n.Address()).Info("memberlist: peer left")
+}
+
+func (e *eventDelegate) NotifyUpdate(n *memberlist.Node) {
+	e.logger.With("peer", n.Name).Debug("memberlist: peer updated")
+}
@@ -0,0 +1,77 @@
+package mesh
+
+import (
+	"github.com/weaveworks/mesh"
+)
+
+// meshTransport is the Transport backed by weaveworks/mesh. It was the only
+// backend before the Transport interface was introduced.
+type meshTransport struct {
+	router *mesh.Router
+	topic  string
+	send   mesh.Gossip
+}
+
+// NewMeshTransport wraps an already-initialized weaveworks/mesh Router,
+// recording topic as the gossip channel used for cluster state; the channel
+// itself is opened on the Router by RegisterReceiver. Silences
+// and NotificationInfos each need their own channel, so callers construct
+// one meshTransport per topic (e.g. "silences", "nflog") sharing the same
+// Router.
+func NewMeshTransport(router *mesh.Router, topic string) *meshTransport {
+	return &meshTransport{router: router, topic: topic}
+}
+
+func (t *meshTransport) Broadcast(d GossipData) {
+	t.send.GossipBroadcast(gossipDataAdapter{d})
+}
+
+func (t *meshTransport) Unicast(peer string, b []byte) error {
+	name, err := mesh.PeerNameFromString(peer)
+	if err != nil {
+		return err
+	}
+	return t.send.GossipUnicast(name, b)
+}
+
+func (t *meshTransport) RegisterReceiver(g Gossiper) {
+	t.send = t.router.NewGossip(t.topic, gossiperAdapter{g})
+}
+
+// gossiperAdapter adapts our Gossiper to mesh.Gossiper.
+type gossiperAdapter struct {
+	g Gossiper
+}
+
+func (a gossiperAdapter) Gossip() mesh.GossipData {
+	return gossipDataAdapter{a.g.Gossip()}
+}
+
+func (a gossiperAdapter) OnGossip(b []byte) (mesh.GossipData, error) {
+	d, err := a.g.OnGossip(b)
+	if err != nil || d == nil {
+		return nil, err
+	}
+	return gossipDataAdapter{d}, nil
+}
+
+func (a gossiperAdapter) OnGossipBroadcast(src mesh.PeerName, b []byte) (mesh.GossipData, error) {
+	d, err := a.g.OnGossipBroadcast(src.String(), b)
+	if err != nil || d == nil {
+		return nil, err
+	}
+	return gossipDataAdapter{d}, nil
+}
+
+func (a gossiperAdapter) OnGossipUnicast(src mesh.PeerName, b []byte) error {
+	return a.g.OnGossipUnicast(src.String(), b)
+}
+
+// gossipDataAdapter adapts our GossipData to mesh.GossipData.
+type gossipDataAdapter struct {
+	GossipData
+}
+
+func (a gossipDataAdapter) Merge(other mesh.GossipData) mesh.GossipData {
+	return gossipDataAdapter{a.GossipData.Merge(other.(gossipDataAdapter).GossipData)}
+}
@@ -0,0 +1,785 @@
+// Package pb holds the wire types for the cluster gossip and snapshot
+// format described by pb.proto. It is hand-written rather than generated,
+// since this tree has no protoc-gen-gogo toolchain available; keep it in
+// sync with pb.proto by hand when either changes.
+package pb
+
+import (
+	fmt "fmt"
+	io "io"
+
+	timestamp "github.com/golang/protobuf/ptypes/timestamp"
+)
+
+// Matcher is the wire representation of types.Matcher.
+type Matcher struct {
+	Name    string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Value   string `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	IsRegex bool   `protobuf:"varint,3,opt,name=is_regex,json=isRegex,proto3" json:"is_regex,omitempty"`
+}
+
+func (m *Matcher) Reset()         { *m = Matcher{} }
+func (m *Matcher) String() string { return fmt.Sprintf("%+v", *m) }
+func (*Matcher) ProtoMessage()    {}
+
+// Silence is the wire representation of types.Silence.
+type Silence struct {
+	Id        []byte               `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Matchers  []*Matcher           `protobuf:"bytes,2,rep,name=matchers" json:"matchers,omitempty"`
+	StartsAt  *timestamp.Timestamp `protobuf:"bytes,3,opt,name=starts_at,json=startsAt" json:"starts_at,omitempty"`
+	EndsAt    *timestamp.Timestamp `protobuf:"bytes,4,opt,name=ends_at,json=endsAt" json:"ends_at,omitempty"`
+	UpdatedAt *timestamp.Timestamp `protobuf:"bytes,5,opt,name=updated_at,json=updatedAt" json:"updated_at,omitempty"`
+	CreatedBy string               `protobuf:"bytes,6,opt,name=created_by,json=createdBy,proto3" json:"created_by,omitempty"`
+	Comment   string               `protobuf:"bytes,7,opt,name=comment,proto3" json:"comment,omitempty"`
+}
+
+func (m *Silence) Reset()         { *m = Silence{} }
+func (m *Silence) String() string { return fmt.Sprintf("%+v", *m) }
+func (*Silence) ProtoMessage()    {}
+
+// SilenceSet is the full gossiped state of Silences.
+type SilenceSet struct {
+	Silences []*Silence `protobuf:"bytes,1,rep,name=silences" json:"silences,omitempty"`
+}
+
+func (m *SilenceSet) Reset()         { *m = SilenceSet{} }
+func (m *SilenceSet) String() string { return fmt.Sprintf("%+v", *m) }
+func (*SilenceSet) ProtoMessage()    {}
+
+// NotificationEntry is the wire representation of a single notificationEntry.
+type NotificationEntry struct {
+	Alert     uint64               `protobuf:"varint,1,opt,name=alert,proto3" json:"alert,omitempty"`
+	Receiver  string               `protobuf:"bytes,2,opt,name=receiver,proto3" json:"receiver,omitempty"`
+	Resolved  bool                 `protobuf:"varint,3,opt,name=resolved,proto3" json:"resolved,omitempty"`
+	Timestamp *timestamp.Timestamp `protobuf:"bytes,4,opt,name=timestamp" json:"timestamp,omitempty"`
+}
+
+func (m *NotificationEntry) Reset()         { *m = NotificationEntry{} }
+func (m *NotificationEntry) String() string { return fmt.Sprintf("%+v", *m) }
+func (*NotificationEntry) ProtoMessage()    {}
+
+// NotificationSet is the full gossiped state of NotificationInfos.
+type NotificationSet struct {
+	Entries []*NotificationEntry `protobuf:"bytes,1,rep,name=entries" json:"entries,omitempty"`
+}
+
+func (m *NotificationSet) Reset()         { *m = NotificationSet{} }
+func (m *NotificationSet) String() string { return fmt.Sprintf("%+v", *m) }
+func (*NotificationSet) ProtoMessage()    {}
+
+// --- wire encoding helpers -------------------------------------------------
+
+func encodeVarint(dAtA []byte, offset int, v uint64) int {
+	offset -= sov(v)
+	base := offset
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return base
+}
+
+func sov(x uint64) (n int) {
+	for {
+		n++
+		x >>= 7
+		if x == 0 {
+			break
+		}
+	}
+	return n
+}
+
+func sizeMessage(m interface{ Size() int }) int {
+	if m == nil {
+		return 0
+	}
+	n := m.Size()
+	return n + sov(uint64(n)) + 1
+}
+
+// sizeTimestamp is sizeMessage's equivalent for *timestamp.Timestamp, which
+// comes from github.com/golang/protobuf/ptypes/timestamp and exposes only
+// XXX_Size, not the Size() method sizeMessage requires of its argument.
+func sizeTimestamp(ts *timestamp.Timestamp) int {
+	if ts == nil {
+		return 0
+	}
+	var n int
+	if ts.Seconds != 0 {
+		n += 1 + sov(uint64(ts.Seconds))
+	}
+	if ts.Nanos != 0 {
+		n += 1 + sov(uint64(ts.Nanos))
+	}
+	return n + sov(uint64(n)) + 1
+}
+
+// --- Matcher ---------------------------------------------------------------
+
+func (m *Matcher) Size() (n int) {
+	if l := len(m.Name); l > 0 {
+		n += 1 + l + sov(uint64(l))
+	}
+	if l := len(m.Value); l > 0 {
+		n += 1 + l + sov(uint64(l))
+	}
+	if m.IsRegex {
+		n += 2
+	}
+	return n
+}
+
+func (m *Matcher) Marshal() ([]byte, error) {
+	size := m.Size()
+	dAtA := make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Matcher) MarshalTo(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	if m.IsRegex {
+		i--
+		if m.IsRegex {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x18
+	}
+	if l := len(m.Value); l > 0 {
+		i -= l
+		copy(dAtA[i:], m.Value)
+		i = encodeVarint(dAtA, i, uint64(l))
+		i--
+		dAtA[i] = 0x12
+	}
+	if l := len(m.Name); l > 0 {
+		i -= l
+		copy(dAtA[i:], m.Name)
+		i = encodeVarint(dAtA, i, uint64(l))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *Matcher) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	for i := 0; i < l; {
+		tag, n, err := readTag(dAtA[i:])
+		if err != nil {
+			return err
+		}
+		i += n
+		field, wire := tag>>3, tag&7
+		switch field {
+		case 1:
+			s, n, err := readString(dAtA[i:], wire)
+			if err != nil {
+				return err
+			}
+			m.Name = s
+			i += n
+		case 2:
+			s, n, err := readString(dAtA[i:], wire)
+			if err != nil {
+				return err
+			}
+			m.Value = s
+			i += n
+		case 3:
+			v, n, err := readVarint(dAtA[i:], wire)
+			if err != nil {
+				return err
+			}
+			m.IsRegex = v != 0
+			i += n
+		default:
+			n, err := skipField(dAtA[i:], wire)
+			if err != nil {
+				return err
+			}
+			i += n
+		}
+	}
+	return nil
+}
+
+// --- Silence -----------------------------------------------------------
+
+func (m *Silence) Size() (n int) {
+	if l := len(m.Id); l > 0 {
+		n += 1 + l + sov(uint64(l))
+	}
+	for _, e := range m.Matchers {
+		n += sizeMessage(e)
+	}
+	n += sizeTimestamp(m.StartsAt)
+	n += sizeTimestamp(m.EndsAt)
+	n += sizeTimestamp(m.UpdatedAt)
+	if l := len(m.CreatedBy); l > 0 {
+		n += 1 + l + sov(uint64(l))
+	}
+	if l := len(m.Comment); l > 0 {
+		n += 1 + l + sov(uint64(l))
+	}
+	return n
+}
+
+func (m *Silence) Marshal() ([]byte, error) {
+	size := m.Size()
+	dAtA := make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Silence) MarshalTo(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	if l := len(m.Comment); l > 0 {
+		i -= l
+		copy(dAtA[i:], m.Comment)
+		i = encodeVarint(dAtA, i, uint64(l))
+		i--
+		dAtA[i] = 0x3a
+	}
+	if l := len(m.CreatedBy); l > 0 {
+		i -= l
+		copy(dAtA[i:], m.CreatedBy)
+		i = encodeVarint(dAtA, i, uint64(l))
+		i--
+		dAtA[i] = 0x32
+	}
+	if m.UpdatedAt != nil {
+		var err error
+		i, err = marshalTimestamp(dAtA, i, m.UpdatedAt, 0x2a)
+		if err != nil {
+			return 0, err
+		}
+	}
+	if m.EndsAt != nil {
+		var err error
+		i, err = marshalTimestamp(dAtA, i, m.EndsAt, 0x22)
+		if err != nil {
+			return 0, err
+		}
+	}
+	if m.StartsAt != nil {
+		var err error
+		i, err = marshalTimestamp(dAtA, i, m.StartsAt, 0x1a)
+		if err != nil {
+			return 0, err
+		}
+	}
+	for j := len(m.Matchers) - 1; j >= 0; j-- {
+		b, err := m.Matchers[j].Marshal()
+		if err != nil {
+			return 0, err
+		}
+		i -= len(b)
+		copy(dAtA[i:], b)
+		i = encodeVarint(dAtA, i, uint64(len(b)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if l := len(m.Id); l > 0 {
+		i -= l
+		copy(dAtA[i:], m.Id)
+		i = encodeVarint(dAtA, i, uint64(l))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *Silence) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	for i := 0; i < l; {
+		tag, n, err := readTag(dAtA[i:])
+		if err != nil {
+			return err
+		}
+		i += n
+		field, wire := tag>>3, tag&7
+		switch field {
+		case 1:
+			b, n, err := readBytes(dAtA[i:], wire)
+			if err != nil {
+				return err
+			}
+			m.Id = b
+			i += n
+		case 2:
+			b, n, err := readBytes(dAtA[i:], wire)
+			if err != nil {
+				return err
+			}
+			mr := &Matcher{}
+			if err := mr.Unmarshal(b); err != nil {
+				return err
+			}
+			m.Matchers = append(m.Matchers, mr)
+			i += n
+		case 3, 4, 5:
+			b, n, err := readBytes(dAtA[i:], wire)
+			if err != nil {
+				return err
+			}
+			ts := &timestamp.Timestamp{}
+			if err := unmarshalTimestamp(ts, b); err != nil {
+				return err
+			}
+			switch field {
+			case 3:
+				m.StartsAt = ts
+			case 4:
+				m.EndsAt = ts
+			case 5:
+				m.UpdatedAt = ts
+			}
+			i += n
+		case 6:
+			s, n, err := readString(dAtA[i:], wire)
+			if err != nil {
+				return err
+			}
+			m.CreatedBy = s
+			i += n
+		case 7:
+			s, n, err := readString(dAtA[i:], wire)
+			if err != nil {
+				return err
+			}
+			m.Comment = s
+			i += n
+		default:
+			n, err := skipField(dAtA[i:], wire)
+			if err != nil {
+				return err
+			}
+			i += n
+		}
+	}
+	return nil
+}
+
+// --- SilenceSet --------------------------------------------------------
+
+func (m *SilenceSet) Size() (n int) {
+	for _, e := range m.Silences {
+		n += sizeMessage(e)
+	}
+	return n
+}
+
+func (m *SilenceSet) Marshal() ([]byte, error) {
+	size := m.Size()
+	dAtA := make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *SilenceSet) MarshalTo(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	for j := len(m.Silences) - 1; j >= 0; j-- {
+		b, err := m.Silences[j].Marshal()
+		if err != nil {
+			return 0, err
+		}
+		i -= len(b)
+		copy(dAtA[i:], b)
+		i = encodeVarint(dAtA, i, uint64(len(b)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *SilenceSet) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	for i := 0; i < l; {
+		tag, n, err := readTag(dAtA[i:])
+		if err != nil {
+			return err
+		}
+		i += n
+		field, wire := tag>>3, tag&7
+		switch field {
+		case 1:
+			b, n, err := readBytes(dAtA[i:], wire)
+			if err != nil {
+				return err
+			}
+			s := &Silence{}
+			if err := s.Unmarshal(b); err != nil {
+				return err
+			}
+			m.Silences = append(m.Silences, s)
+			i += n
+		default:
+			n, err := skipField(dAtA[i:], wire)
+			if err != nil {
+				return err
+			}
+			i += n
+		}
+	}
+	return nil
+}
+
+// --- NotificationEntry ---------------------------------------------------
+
+func (m *NotificationEntry) Size() (n int) {
+	if m.Alert != 0 {
+		n += 1 + sov(m.Alert)
+	}
+	if l := len(m.Receiver); l > 0 {
+		n += 1 + l + sov(uint64(l))
+	}
+	if m.Resolved {
+		n += 2
+	}
+	n += sizeTimestamp(m.Timestamp)
+	return n
+}
+
+func (m *NotificationEntry) Marshal() ([]byte, error) {
+	size := m.Size()
+	dAtA := make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *NotificationEntry) MarshalTo(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	if m.Timestamp != nil {
+		var err error
+		i, err = marshalTimestamp(dAtA, i, m.Timestamp, 0x22)
+		if err != nil {
+			return 0, err
+		}
+	}
+	if m.Resolved {
+		i--
+		if m.Resolved {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x18
+	}
+	if l := len(m.Receiver); l > 0 {
+		i -= l
+		copy(dAtA[i:], m.Receiver)
+		i = encodeVarint(dAtA, i, uint64(l))
+		i--
+		dAtA[i] = 0x12
+	}
+	if m.Alert != 0 {
+		i = encodeVarint(dAtA, i, m.Alert)
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *NotificationEntry) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	for i := 0; i < l; {
+		tag, n, err := readTag(dAtA[i:])
+		if err != nil {
+			return err
+		}
+		i += n
+		field, wire := tag>>3, tag&7
+		switch field {
+		case 1:
+			v, n, err := readVarint(dAtA[i:], wire)
+			if err != nil {
+				return err
+			}
+			m.Alert = v
+			i += n
+		case 2:
+			s, n, err := readString(dAtA[i:], wire)
+			if err != nil {
+				return err
+			}
+			m.Receiver = s
+			i += n
+		case 3:
+			v, n, err := readVarint(dAtA[i:], wire)
+			if err != nil {
+				return err
+			}
+			m.Resolved = v != 0
+			i += n
+		case 4:
+			b, n, err := readBytes(dAtA[i:], wire)
+			if err != nil {
+				return err
+			}
+			ts := &timestamp.Timestamp{}
+			if err := unmarshalTimestamp(ts, b); err != nil {
+				return err
+			}
+			m.Timestamp = ts
+			i += n
+		default:
+			n, err := skipField(dAtA[i:], wire)
+			if err != nil {
+				return err
+			}
+			i += n
+		}
+	}
+	return nil
+}
+
+// --- NotificationSet ------------------------------------------------------
+
+func (m *NotificationSet) Size() (n int) {
+	for _, e := range m.Entries {
+		n += sizeMessage(e)
+	}
+	return n
+}
+
+func (m *NotificationSet) Marshal() ([]byte, error) {
+	size := m.Size()
+	dAtA := make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *NotificationSet) MarshalTo(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	for j := len(m.Entries) - 1; j >= 0; j-- {
+		b, err := m.Entries[j].Marshal()
+		if err != nil {
+			return 0, err
+		}
+		i -= len(b)
+		copy(dAtA[i:], b)
+		i = encodeVarint(dAtA, i, uint64(len(b)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *NotificationSet) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	for i := 0; i < l; {
+		tag, n, err := readTag(dAtA[i:])
+		if err != nil {
+			return err
+		}
+		i += n
+		field, wire := tag>>3, tag&7
+		switch field {
+		case 1:
+			b, n, err := readBytes(dAtA[i:], wire)
+			if err != nil {
+				return err
+			}
+			e := &NotificationEntry{}
+			if err := e.Unmarshal(b); err != nil {
+				return err
+			}
+			m.Entries = append(m.Entries, e)
+			i += n
+		default:
+			n, err := skipField(dAtA[i:], wire)
+			if err != nil {
+				return err
+			}
+			i += n
+		}
+	}
+	return nil
+}
+
+// --- google.protobuf.Timestamp helpers -------------------------------------
+
+// marshalTimestamp writes ts as a length-delimited field with the given tag
+// byte, growing backwards from offset i the same way the rest of MarshalTo
+// does, and returns the new offset.
+func marshalTimestamp(dAtA []byte, i int, ts *timestamp.Timestamp, tag byte) (int, error) {
+	b, err := marshalTimestampValue(ts)
+	if err != nil {
+		return 0, err
+	}
+	i -= len(b)
+	copy(dAtA[i:], b)
+	i = encodeVarint(dAtA, i, uint64(len(b)))
+	i--
+	dAtA[i] = tag
+	return i, nil
+}
+
+func marshalTimestampValue(ts *timestamp.Timestamp) ([]byte, error) {
+	size := 0
+	if ts.Seconds != 0 {
+		size += 1 + sov(uint64(ts.Seconds))
+	}
+	if ts.Nanos != 0 {
+		size += 1 + sov(uint64(ts.Nanos))
+	}
+	dAtA := make([]byte, size)
+	i := size
+	if ts.Nanos != 0 {
+		i = encodeVarint(dAtA, i, uint64(ts.Nanos))
+		i--
+		dAtA[i] = 0x10
+	}
+	if ts.Seconds != 0 {
+		i = encodeVarint(dAtA, i, uint64(ts.Seconds))
+		i--
+		dAtA[i] = 0x8
+	}
+	return dAtA[i:], nil
+}
+
+func unmarshalTimestamp(ts *timestamp.Timestamp, dAtA []byte) error {
+	l := len(dAtA)
+	for i := 0; i < l; {
+		tag, n, err := readTag(dAtA[i:])
+		if err != nil {
+			return err
+		}
+		i += n
+		field, wire := tag>>3, tag&7
+		switch field {
+		case 1:
+			v, n, err := readVarint(dAtA[i:], wire)
+			if err != nil {
+				return err
+			}
+			ts.Seconds = int64(v)
+			i += n
+		case 2:
+			v, n, err := readVarint(dAtA[i:], wire)
+			if err != nil {
+				return err
+			}
+			ts.Nanos = int32(v)
+			i += n
+		default:
+			n, err := skipField(dAtA[i:], wire)
+			if err != nil {
+				return err
+			}
+			i += n
+		}
+	}
+	return nil
+}
+
+// --- low-level wire format decoding -----------------------------------------
+
+func readTag(dAtA []byte) (uint64, int, error) {
+	return readVarintRaw(dAtA)
+}
+
+func readVarintRaw(dAtA []byte) (uint64, int, error) {
+	var v uint64
+	var shift uint
+	for i := 0; i < len(dAtA); i++ {
+		b := dAtA[i]
+		v |= uint64(b&0x7f) << shift
+		if b < 0x80 {
+			return v, i + 1, nil
+		}
+		shift += 7
+		if shift >= 64 {
+			return 0, 0, fmt.Errorf("pb: varint overflow")
+		}
+	}
+	return 0, 0, io.ErrUnexpectedEOF
+}
+
+func readVarint(dAtA []byte, wire uint64) (uint64, int, error) {
+	if wire != 0 {
+		return 0, 0, fmt.Errorf("pb: unexpected wire type %d for varint field", wire)
+	}
+	return readVarintRaw(dAtA)
+}
+
+func readBytes(dAtA []byte, wire uint64) ([]byte, int, error) {
+	if wire != 2 {
+		return nil, 0, fmt.Errorf("pb: unexpected wire type %d for length-delimited field", wire)
+	}
+	l, n, err := readVarintRaw(dAtA)
+	if err != nil {
+		return nil, 0, err
+	}
+	end := n + int(l)
+	if end < n || end > len(dAtA) {
+		return nil, 0, io.ErrUnexpectedEOF
+	}
+	b := make([]byte, l)
+	copy(b, dAtA[n:end])
+	return b, end, nil
+}
+
+func readString(dAtA []byte, wire uint64) (string, int, error) {
+	b, n, err := readBytes(dAtA, wire)
+	if err != nil {
+		return "", 0, err
+	}
+	return string(b), n, nil
+}
+
+// skipField advances past a field of the given wire type whose tag has
+// already been consumed, so that Unmarshal can tolerate unknown fields added
+// by a newer peer.
+func skipField(dAtA []byte, wire uint64) (int, error) {
+	switch wire {
+	case 0:
+		_, n, err := readVarintRaw(dAtA)
+		return n, err
+	case 1:
+		if len(dAtA) < 8 {
+			return 0, io.ErrUnexpectedEOF
+		}
+		return 8, nil
+	case 2:
+		l, n, err := readVarintRaw(dAtA)
+		if err != nil {
+			return 0, err
+		}
+		end := n + int(l)
+		if end < n || end > len(dAtA) {
+			return 0, io.ErrUnexpectedEOF
+		}
+		return end, nil
+	case 5:
+		if len(dAtA) < 4 {
+			return 0, io.ErrUnexpectedEOF
+		}
+		return 4, nil
+	default:
+		return 0, fmt.Errorf("pb: unknown wire type %d", wire)
+	}
+}
@@ -9,36 +9,76 @@ import (
 	"github.com/prometheus/common/log"
 	"github.com/prometheus/common/model"
 	"github.com/satori/go.uuid"
-	"github.com/weaveworks/mesh"
 )
 
 type NotificationInfos struct {
 	st     *notificationState
-	send   mesh.Gossip
+	send   Transport
 	logger log.Logger
+	subs   *notificationSubscribers
 }
 
 func NewNotificationInfos(logger log.Logger) *NotificationInfos {
 	return &NotificationInfos{
 		logger: logger,
 		st:     newNotificationState(),
+		subs:   newNotificationSubscribers(),
 	}
 }
 
-func (ni *NotificationInfos) Register(g mesh.Gossip) {
-	ni.send = g
+// Subscribe returns a channel of NotificationEvents for every change merged
+// into ni, whether it originated locally via Set or from a peer via gossip,
+// and a cancel function that unsubscribes and closes the channel. Slow
+// subscribers lose their oldest buffered event rather than stalling merges.
+func (ni *NotificationInfos) Subscribe() (<-chan NotificationEvent, func()) {
+	return ni.subs.subscribe()
 }
 
-func (ni *NotificationInfos) Gossip() mesh.GossipData {
+// publish translates a merge diff into NotificationEvents. sync marks diffs
+// produced by a full-state merge (ActionSync); otherwise the action is
+// derived per key from whether it existed before the merge.
+func (ni *NotificationInfos) publish(sync bool, diff map[string]notificationDiff) {
+	for _, d := range diff {
+		e := d.new
+		act := ActionSync
+		if !sync {
+			if d.existed {
+				act = ActionUpdate
+			} else {
+				act = ActionCreate
+			}
+		}
+		ni.subs.publish(NotificationEvent{
+			Action: act,
+			Info: &types.NotifyInfo{
+				Alert:     e.Alert,
+				Receiver:  e.Receiver,
+				Resolved:  e.Resolved,
+				Timestamp: e.Timestamp,
+			},
+			PrevUpdatedAt: d.prev.Timestamp,
+		})
+	}
+}
+
+// Register wires ni up to t so it can broadcast local updates and receive
+// gossip and unicasts addressed to it.
+func (ni *NotificationInfos) Register(t Transport) {
+	ni.send = t
+	t.RegisterReceiver(ni)
+}
+
+func (ni *NotificationInfos) Gossip() GossipData {
 	return ni.st.copy()
 }
 
-func (ni *NotificationInfos) OnGossip(b []byte) (mesh.GossipData, error) {
-	set, err := decodeNotificationSet(b)
+func (ni *NotificationInfos) OnGossip(b []byte) (GossipData, error) {
+	set, err := decodeNotificationSet(b, ni.logger)
 	if err != nil {
 		return nil, err
 	}
-	d := ni.st.mergeDelta(set)
+	d, diff := ni.st.mergeDelta(set)
+	ni.publish(false, diff)
 	// The delta is newly created and we are the only one holding it so far.
 	// Thus, we can access without locking.
 	if len(d.set) == 0 {
@@ -47,43 +87,55 @@ func (ni *NotificationInfos) OnGossip(b []byte) (mesh.GossipData, error) {
 	return d, nil
 }
 
-func (ni *NotificationInfos) OnGossipBroadcast(_ mesh.PeerName, b []byte) (mesh.GossipData, error) {
-	set, err := decodeNotificationSet(b)
+func (ni *NotificationInfos) OnGossipBroadcast(_ string, b []byte) (GossipData, error) {
+	set, err := decodeNotificationSet(b, ni.logger)
 	if err != nil {
 		return nil, err
 	}
-	return ni.st.mergeDelta(set), nil
+	d, diff := ni.st.mergeDelta(set)
+	ni.publish(false, diff)
+	return d, nil
 }
 
-func (ni *NotificationInfos) OnGossipUnicast(_ mesh.PeerName, b []byte) error {
-	set, err := decodeNotificationSet(b)
+func (ni *NotificationInfos) OnGossipUnicast(_ string, b []byte) error {
+	set, err := decodeNotificationSet(b, ni.logger)
 	if err != nil {
 		return err
 	}
-	ni.st.mergeComplete(set)
+	diff := ni.st.mergeComplete(set)
+	ni.publish(true, diff)
 	return nil
 }
 
 func (ni *NotificationInfos) Set(ns ...*types.NotifyInfo) error {
 	set := map[string]notificationEntry{}
 	for _, n := range ns {
-		k := fmt.Sprintf("%x:%s", n.Alert, n.Receiver)
+		// Defensive: n.Timestamp comes from the alert pipeline rather than
+		// raw user input, but an out-of-range value would still panic
+		// mustTimestamp (encode.go) the next time this state is gossiped.
+		if !validTimestamp(n.Timestamp) {
+			return fmt.Errorf("notification timestamp outside representable range")
+		}
+		k := notificationKey(n.Alert, n.Receiver)
 		set[k] = notificationEntry{
+			Alert:     n.Alert,
+			Receiver:  n.Receiver,
 			Resolved:  n.Resolved,
 			Timestamp: n.Timestamp,
 		}
 	}
 	update := &notificationState{set: set}
 
-	ni.st.Merge(update)
-	ni.send.GossipBroadcast(update)
+	_, diff := ni.st.mergeDelta(update)
+	ni.publish(false, diff)
+	ni.send.Broadcast(update)
 	return nil
 }
 
 func (ni *NotificationInfos) Get(dest string, fps ...model.Fingerprint) ([]*types.NotifyInfo, error) {
 	res := make([]*types.NotifyInfo, 0, len(fps))
 	for _, fp := range fps {
-		k := fmt.Sprintf("%x:%s", fp, dest)
+		k := notificationKey(fp, dest)
 		if e, ok := ni.st.set[k]; ok {
 			res = append(res, &types.NotifyInfo{
 				Alert:     fp,
@@ -101,8 +153,9 @@ func (ni *NotificationInfos) Get(dest string, fps ...model.Fingerprint) ([]*type
 type Silences struct {
 	st     *silenceState
 	mk     types.Marker
-	send   mesh.Gossip
+	send   Transport
 	logger log.Logger
+	subs   *silenceSubscribers
 }
 
 func NewSilences(mk types.Marker, logger log.Logger) *Silences {
@@ -110,11 +163,48 @@ func NewSilences(mk types.Marker, logger log.Logger) *Silences {
 		st:     newSilenceState(),
 		mk:     mk,
 		logger: logger,
+		subs:   newSilenceSubscribers(),
+	}
+}
+
+// Subscribe returns a channel of SilenceEvents for every change merged into
+// s, whether it originated locally via Set/Del or from a peer via gossip,
+// and a cancel function that unsubscribes and closes the channel. Slow
+// subscribers lose their oldest buffered event rather than stalling merges.
+func (s *Silences) Subscribe() (<-chan SilenceEvent, func()) {
+	return s.subs.subscribe()
+}
+
+// publish translates a merge diff into SilenceEvents. sync marks diffs
+// produced by a full-state merge (ActionSync); otherwise the action is
+// derived per key from whether it existed before the merge.
+func (s *Silences) publish(sync bool, diff map[uuid.UUID]silenceDiff) {
+	for _, d := range diff {
+		act := ActionSync
+		if !sync {
+			if d.prev == nil {
+				act = ActionCreate
+			} else {
+				act = ActionUpdate
+			}
+		}
+		var prevUpdatedAt time.Time
+		if d.prev != nil {
+			prevUpdatedAt = d.prev.UpdatedAt
+		}
+		s.subs.publish(SilenceEvent{
+			Action:        act,
+			Silence:       d.new,
+			PrevUpdatedAt: prevUpdatedAt,
+		})
 	}
 }
 
-func (s *Silences) Register(g mesh.Gossip) {
-	s.send = g
+// Register wires s up to t so it can broadcast local updates and receive
+// gossip and unicasts addressed to it.
+func (s *Silences) Register(t Transport) {
+	s.send = t
+	t.RegisterReceiver(s)
 }
 
 func (s *Silences) Mutes(lset model.LabelSet) bool {
@@ -149,14 +239,19 @@ func (s *Silences) Set(sil *types.Silence) (uuid.UUID, error) {
 	}
 	sil.UpdatedAt = time.Now()
 
+	if err := validateSilence(sil); err != nil {
+		return uuid.Nil, err
+	}
+
 	update := &silenceState{
 		set: map[uuid.UUID]*types.Silence{
 			sil.ID: sil,
 		},
 	}
 
-	s.st.Merge(update)
-	s.send.GossipBroadcast(update)
+	diff := s.st.mergeComplete(update)
+	s.publish(false, diff)
+	s.send.Broadcast(update)
 
 	return sil.ID, nil
 }
@@ -177,17 +272,31 @@ func (s *Silences) Del(id uuid.UUID) error {
 	newSil := *sil
 	newSil.UpdatedAt = now
 	newSil.EndsAt = now
+	if !newSil.StartsAt.Before(newSil.EndsAt) {
+		// The silence never became active. Back-date StartsAt instead of
+		// gossiping a StartsAt >= EndsAt tombstone that validateSilence, and
+		// therefore every peer's OnGossip*, would reject.
+		newSil.StartsAt = newSil.EndsAt.Add(-time.Second)
+	}
 
 	if err := newSil.Init(); err != nil {
 		return fmt.Errorf("silence init: %s", err)
 	}
+	if err := validateSilence(&newSil); err != nil {
+		return err
+	}
 	update := &silenceState{
 		set: map[uuid.UUID]*types.Silence{
 			newSil.ID: &newSil,
 		},
 	}
-	s.st.Merge(update)
-	s.send.GossipBroadcast(update)
+	s.st.mergeComplete(update)
+	s.subs.publish(SilenceEvent{
+		Action:        ActionDelete,
+		Silence:       &newSil,
+		PrevUpdatedAt: sil.UpdatedAt,
+	})
+	s.send.Broadcast(update)
 
 	return nil
 }
@@ -204,16 +313,18 @@ func (s *Silences) Get(id uuid.UUID) (*types.Silence, error) {
 	return sil, nil
 }
 
-func (s *Silences) Gossip() mesh.GossipData {
+func (s *Silences) Gossip() GossipData {
 	return s.st.copy()
 }
 
-func (s *Silences) OnGossip(b []byte) (mesh.GossipData, error) {
-	set, err := decodeSilenceSet(b)
+func (s *Silences) OnGossip(b []byte) (GossipData, error) {
+	set, err := decodeSilenceSet(b, s.logger)
 	if err != nil {
 		return nil, err
 	}
-	d := s.st.mergeDelta(set)
+	validateSilenceState(set, s.logger)
+	d, diff := s.st.mergeDelta(set)
+	s.publish(false, diff)
 	// The delta is newly created and we are the only one holding it so far.
 	// Thus, we can access without locking.
 	if len(d.set) == 0 {
@@ -222,20 +333,24 @@ func (s *Silences) OnGossip(b []byte) (mesh.GossipData, error) {
 	return d, nil
 }
 
-func (s *Silences) OnGossipBroadcast(_ mesh.PeerName, b []byte) (mesh.GossipData, error) {
-	set, err := decodeSilenceSet(b)
+func (s *Silences) OnGossipBroadcast(_ string, b []byte) (GossipData, error) {
+	set, err := decodeSilenceSet(b, s.logger)
 	if err != nil {
 		return nil, err
 	}
-	d := s.st.mergeDelta(set)
+	validateSilenceState(set, s.logger)
+	d, diff := s.st.mergeDelta(set)
+	s.publish(false, diff)
 	return d, nil
 }
 
-func (s *Silences) OnGossipUnicast(_ mesh.PeerName, b []byte) error {
-	set, err := decodeSilenceSet(b)
+func (s *Silences) OnGossipUnicast(_ string, b []byte) error {
+	set, err := decodeSilenceSet(b, s.logger)
 	if err != nil {
 		return err
 	}
-	s.st.mergeComplete(set)
+	validateSilenceState(set, s.logger)
+	diff := s.st.mergeComplete(set)
+	s.publish(true, diff)
 	return nil
 }
\ No newline at end of file
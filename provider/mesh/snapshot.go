@@ -0,0 +1,137 @@
+package mesh
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/prometheus/common/log"
+)
+
+const (
+	silencesFilename = "silences"
+	nflogFilename    = "nflog"
+)
+
+// Snapshot writes the full state of ni to w as a pb.NotificationSet, so it
+// can be reloaded with LoadSnapshot after a restart.
+func (ni *NotificationInfos) Snapshot(w io.Writer) error {
+	b, err := notificationStateToPB(ni.st.copy()).Marshal()
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// LoadSnapshot replaces ni's state with the pb.NotificationSet read from r.
+// It must be called before Register, since it does not publish events or
+// gossip the loaded state to peers.
+func (ni *NotificationInfos) LoadSnapshot(r io.Reader) error {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	st, err := decodeNotificationSet(b, ni.logger)
+	if err != nil {
+		return err
+	}
+	ni.st = st
+	return nil
+}
+
+// Snapshot writes the full state of s to w as a pb.SilenceSet, so it can be
+// reloaded with LoadSnapshot after a restart.
+func (s *Silences) Snapshot(w io.Writer) error {
+	b, err := silenceStateToPB(s.st.copy()).Marshal()
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// LoadSnapshot replaces s's state with the pb.SilenceSet read from r. It
+// must be called before Register, since it does not publish events or
+// gossip the loaded state to peers.
+func (s *Silences) LoadSnapshot(r io.Reader) error {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	st, err := decodeSilenceSet(b, s.logger)
+	if err != nil {
+		return err
+	}
+	s.st = st
+	return nil
+}
+
+// Snapshotter periodically persists NotificationInfos and Silences to
+// <dir>/nflog and <dir>/silences so cluster members keep their state across
+// restarts instead of relying solely on a resync with peers. The interval is
+// the one passed to NewSnapshotter; this package defines no flag for it.
+type Snapshotter struct {
+	dir      string
+	interval time.Duration
+	ni       *NotificationInfos
+	s        *Silences
+	logger   log.Logger
+}
+
+// NewSnapshotter creates a Snapshotter that writes ni and s to dir every
+// interval. Call LoadSnapshot on ni and s with the files it writes before
+// Register-ing them with a Transport, so a restarted peer rejoins the
+// cluster with its last known state rather than an empty one.
+func NewSnapshotter(dir string, interval time.Duration, ni *NotificationInfos, s *Silences, logger log.Logger) *Snapshotter {
+	return &Snapshotter{dir: dir, interval: interval, ni: ni, s: s, logger: logger}
+}
+
+// Run snapshots ni and s every interval until stop is closed, logging but
+// not aborting on a failed snapshot so a single bad write doesn't halt
+// future attempts.
+func (sn *Snapshotter) Run(stop <-chan struct{}) {
+	t := time.NewTicker(sn.interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-t.C:
+			if err := sn.snapshot(); err != nil {
+				sn.logger.With("err", err).Error("error snapshotting cluster state")
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+func (sn *Snapshotter) snapshot() error {
+	if err := writeAtomic(filepath.Join(sn.dir, nflogFilename), sn.ni.Snapshot); err != nil {
+		return err
+	}
+	return writeAtomic(filepath.Join(sn.dir, silencesFilename), sn.s.Snapshot)
+}
+
+// writeAtomic calls write with a temporary file in the same directory as
+// path and, if it succeeds, renames the temporary file over path so readers
+// never observe a partially written snapshot.
+func writeAtomic(path string, write func(io.Writer) error) error {
+	f, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	defer os.Remove(tmp)
+
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
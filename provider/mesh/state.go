@@ -0,0 +1,246 @@
+package mesh
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/alertmanager/provider/mesh/pb"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/log"
+	"github.com/prometheus/common/model"
+	"github.com/satori/go.uuid"
+)
+
+// GossipData is the unit of state that is exchanged and merged between
+// cluster peers. It intentionally mirrors weaveworks/mesh.GossipData so that
+// existing merge logic can be reused unchanged by any Transport
+// implementation.
+type GossipData interface {
+	// Encode serializes the data into one or more byte slices suitable for
+	// sending over the wire.
+	Encode() [][]byte
+	// Merge merges the other GossipData into this one and returns the
+	// result.
+	Merge(other GossipData) GossipData
+}
+
+// notificationEntry holds the last known notification state for a single
+// alert/receiver pair. Alert and Receiver duplicate what's already encoded
+// in the notificationState map key so that a single entry is enough to
+// rebuild a *types.NotifyInfo, e.g. when publishing a NotificationEvent.
+type notificationEntry struct {
+	Alert     model.Fingerprint
+	Receiver  string
+	Resolved  bool
+	Timestamp time.Time
+}
+
+func (e notificationEntry) equal(o notificationEntry) bool {
+	return e.Resolved == o.Resolved && e.Timestamp.Equal(o.Timestamp)
+}
+
+// notificationState is the gossiped state of NotificationInfos, keyed by a
+// combination of alert fingerprint and receiver.
+type notificationState struct {
+	mtx sync.RWMutex
+	set map[string]notificationEntry
+}
+
+// notificationKey builds the notificationState map key for the given
+// alert/receiver pair.
+func notificationKey(fp model.Fingerprint, receiver string) string {
+	return fmt.Sprintf("%x:%s", fp, receiver)
+}
+
+func newNotificationState() *notificationState {
+	return &notificationState{set: map[string]notificationEntry{}}
+}
+
+func (s *notificationState) copy() *notificationState {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	set := make(map[string]notificationEntry, len(s.set))
+	for k, v := range s.set {
+		set[k] = v
+	}
+	return &notificationState{set: set}
+}
+
+// Encode implements GossipData. It serializes the state as a pb.NotificationSet
+// so the format is forward-compatible and well-defined across peers with
+// clock skew, rather than a Go-specific gob encoding.
+func (s *notificationState) Encode() [][]byte {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	b, err := notificationStateToPB(s).Marshal()
+	if err != nil {
+		panic(err)
+	}
+	return [][]byte{b}
+}
+
+func decodeNotificationSet(b []byte, logger log.Logger) (*notificationState, error) {
+	var set pb.NotificationSet
+	if err := set.Unmarshal(b); err != nil {
+		return nil, err
+	}
+	return notificationStateFromPB(&set, logger)
+}
+
+// Merge implements GossipData. It merges the other notification state into
+// the receiver in place and returns the receiver so callers that only care
+// about side effects, like Gossip(), can ignore the result.
+func (s *notificationState) Merge(other GossipData) GossipData {
+	o := other.(*notificationState)
+	s.mergeComplete(o)
+	return s
+}
+
+// notificationDiff describes what a key's entry looked like before a merge
+// overwrote it and what it holds now, so callers (e.g. publish) can build an
+// event from the diff itself instead of re-reading the map after the
+// merge's lock has been released.
+type notificationDiff struct {
+	prev    notificationEntry
+	new     notificationEntry
+	existed bool
+}
+
+// mergeComplete merges a full remote state into s, keeping the newer entry
+// for any key present in both, and returns the keys that changed along with
+// what they held before and after.
+func (s *notificationState) mergeComplete(o *notificationState) map[string]notificationDiff {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	o.mtx.RLock()
+	defer o.mtx.RUnlock()
+
+	diff := map[string]notificationDiff{}
+	for k, e := range o.set {
+		prev, ok := s.set[k]
+		if ok && prev.equal(e) {
+			continue
+		}
+		if !ok || prev.Timestamp.Before(e.Timestamp) {
+			s.set[k] = e
+			diff[k] = notificationDiff{prev: prev, new: e, existed: ok}
+		}
+	}
+	return diff
+}
+
+// mergeDelta merges o into s like mergeComplete but also returns only the
+// subset of entries that actually changed s, so the result can be
+// re-gossiped as a delta.
+func (s *notificationState) mergeDelta(o *notificationState) (*notificationState, map[string]notificationDiff) {
+	diff := s.mergeComplete(o)
+
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	d := newNotificationState()
+	for k := range diff {
+		d.set[k] = s.set[k]
+	}
+	return d, diff
+}
+
+// silenceState is the gossiped state of Silences, keyed by silence ID.
+type silenceState struct {
+	mtx sync.RWMutex
+	set map[uuid.UUID]*types.Silence
+}
+
+func newSilenceState() *silenceState {
+	return &silenceState{set: map[uuid.UUID]*types.Silence{}}
+}
+
+func (s *silenceState) copy() *silenceState {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	set := make(map[uuid.UUID]*types.Silence, len(s.set))
+	for k, v := range s.set {
+		set[k] = v
+	}
+	return &silenceState{set: set}
+}
+
+// Encode implements GossipData. It serializes the state as a pb.SilenceSet
+// so the format is forward-compatible and well-defined across peers with
+// clock skew, rather than a Go-specific gob encoding.
+func (s *silenceState) Encode() [][]byte {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	b, err := silenceStateToPB(s).Marshal()
+	if err != nil {
+		panic(err)
+	}
+	return [][]byte{b}
+}
+
+func decodeSilenceSet(b []byte, logger log.Logger) (*silenceState, error) {
+	var set pb.SilenceSet
+	if err := set.Unmarshal(b); err != nil {
+		return nil, err
+	}
+	return silenceStateFromPB(&set, logger)
+}
+
+// Merge implements GossipData.
+func (s *silenceState) Merge(other GossipData) GossipData {
+	o := other.(*silenceState)
+	s.mergeComplete(o)
+	return s
+}
+
+// silenceDiff describes what a silence ID pointed to before a merge
+// overwrote it and what it holds now, so callers (e.g. publish) can build an
+// event from the diff itself instead of re-reading the map after the
+// merge's lock has been released. prev is nil when the ID did not exist
+// before.
+type silenceDiff struct {
+	prev *types.Silence
+	new  *types.Silence
+}
+
+// mergeComplete merges a full remote state into s, keeping the silence with
+// the newer UpdatedAt for any ID present in both, and returns the IDs that
+// changed along with what they held before and after.
+func (s *silenceState) mergeComplete(o *silenceState) map[uuid.UUID]silenceDiff {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	o.mtx.RLock()
+	defer o.mtx.RUnlock()
+
+	diff := map[uuid.UUID]silenceDiff{}
+	for k, sil := range o.set {
+		if prev, ok := s.set[k]; !ok || prev.UpdatedAt.Before(sil.UpdatedAt) {
+			s.set[k] = sil
+			diff[k] = silenceDiff{prev: prev, new: sil}
+		}
+	}
+	return diff
+}
+
+// mergeDelta merges o into s like mergeComplete but also returns only the
+// subset of silences that actually changed s, so the result can be
+// re-gossiped as a delta.
+func (s *silenceState) mergeDelta(o *silenceState) (*silenceState, map[uuid.UUID]silenceDiff) {
+	diff := s.mergeComplete(o)
+
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	d := newSilenceState()
+	for k := range diff {
+		d.set[k] = s.set[k]
+	}
+	return d, diff
+}
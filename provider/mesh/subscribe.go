@@ -0,0 +1,156 @@
+package mesh
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/alertmanager/types"
+)
+
+// Action identifies the kind of change a SilenceEvent or NotificationEvent
+// represents, mirroring the action-typed update pattern used by
+// memberlist-based registries (create/delete/update/sync).
+type Action int32
+
+const (
+	// ActionCreate is emitted for a key not previously known to this
+	// process, whether it was just created locally or merged in from a
+	// peer for the first time.
+	ActionCreate Action = iota
+	// ActionUpdate is emitted when a known key's value changed.
+	ActionUpdate
+	// ActionDelete is emitted for silences soft-deleted through
+	// Silences.Del.
+	ActionDelete
+	// ActionSync is emitted for changes folded in by a full state received
+	// from a peer through OnGossipUnicast, as opposed to an incremental
+	// delta or a local Set.
+	ActionSync
+)
+
+// subscriberBufferSize bounds the number of events buffered per subscriber.
+// A subscriber that falls behind loses its oldest buffered event rather than
+// blocking the merge path that produced it.
+const subscriberBufferSize = 128
+
+// SilenceEvent is sent to subscribers of Silences whenever a merge, local or
+// gossiped, changes a silence.
+type SilenceEvent struct {
+	Action        Action
+	Silence       *types.Silence
+	PrevUpdatedAt time.Time
+}
+
+// NotificationEvent is sent to subscribers of NotificationInfos whenever a
+// merge, local or gossiped, changes a notification log entry.
+type NotificationEvent struct {
+	Action        Action
+	Info          *types.NotifyInfo
+	PrevUpdatedAt time.Time
+}
+
+// silenceSubscribers fans SilenceEvents out to an arbitrary number of
+// subscriber channels, dropping the oldest buffered event for any
+// subscriber that cannot keep up.
+type silenceSubscribers struct {
+	mtx  sync.Mutex
+	next int
+	subs map[int]chan SilenceEvent
+}
+
+func newSilenceSubscribers() *silenceSubscribers {
+	return &silenceSubscribers{subs: map[int]chan SilenceEvent{}}
+}
+
+func (r *silenceSubscribers) subscribe() (<-chan SilenceEvent, func()) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	id := r.next
+	r.next++
+	ch := make(chan SilenceEvent, subscriberBufferSize)
+	r.subs[id] = ch
+
+	return ch, func() {
+		r.mtx.Lock()
+		defer r.mtx.Unlock()
+		if c, ok := r.subs[id]; ok {
+			delete(r.subs, id)
+			close(c)
+		}
+	}
+}
+
+func (r *silenceSubscribers) publish(ev SilenceEvent) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	for _, ch := range r.subs {
+		select {
+		case ch <- ev:
+		default:
+			// Drop the oldest buffered event to make room and retry once;
+			// if we still can't send, give up on this event for this
+			// subscriber rather than block the merge path.
+			select {
+			case <-ch:
+			default:
+			}
+			select {
+			case ch <- ev:
+			default:
+			}
+		}
+	}
+}
+
+// notificationSubscribers is the NotificationEvent equivalent of
+// silenceSubscribers.
+type notificationSubscribers struct {
+	mtx  sync.Mutex
+	next int
+	subs map[int]chan NotificationEvent
+}
+
+func newNotificationSubscribers() *notificationSubscribers {
+	return &notificationSubscribers{subs: map[int]chan NotificationEvent{}}
+}
+
+func (r *notificationSubscribers) subscribe() (<-chan NotificationEvent, func()) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	id := r.next
+	r.next++
+	ch := make(chan NotificationEvent, subscriberBufferSize)
+	r.subs[id] = ch
+
+	return ch, func() {
+		r.mtx.Lock()
+		defer r.mtx.Unlock()
+		if c, ok := r.subs[id]; ok {
+			delete(r.subs, id)
+			close(c)
+		}
+	}
+}
+
+func (r *notificationSubscribers) publish(ev NotificationEvent) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	for _, ch := range r.subs {
+		select {
+		case ch <- ev:
+		default:
+			select {
+			case <-ch:
+			default:
+			}
+			select {
+			case ch <- ev:
+			default:
+			}
+		}
+	}
+}
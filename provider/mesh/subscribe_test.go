@@ -0,0 +1,280 @@
+package mesh
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/satori/go.uuid"
+)
+
+func recvSilenceEvent(t *testing.T, ch <-chan SilenceEvent) SilenceEvent {
+	t.Helper()
+	select {
+	case ev := <-ch:
+		return ev
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for SilenceEvent")
+		return SilenceEvent{}
+	}
+}
+
+func recvNotificationEvent(t *testing.T, ch <-chan NotificationEvent) NotificationEvent {
+	t.Helper()
+	select {
+	case ev := <-ch:
+		return ev
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for NotificationEvent")
+		return NotificationEvent{}
+	}
+}
+
+func TestSilencesSubscribe(t *testing.T) {
+	s := NewSilences(nil, nil)
+	s.send = noopTransport{}
+
+	ch, cancel := s.Subscribe()
+	defer cancel()
+
+	now := time.Now()
+	sil := &types.Silence{
+		Matchers: []*types.Matcher{{Name: "alertname", Value: "Test"}},
+		StartsAt: now,
+		EndsAt:   now.Add(time.Hour),
+	}
+	if _, err := s.Set(sil); err != nil {
+		t.Fatalf("Set: %s", err)
+	}
+
+	if ev := recvSilenceEvent(t, ch); ev.Action != ActionCreate {
+		t.Errorf("expected ActionCreate, got %v", ev.Action)
+	}
+}
+
+func TestSilencesSubscribeUpdate(t *testing.T) {
+	s := NewSilences(nil, nil)
+	s.send = noopTransport{}
+
+	ch, cancel := s.Subscribe()
+	defer cancel()
+
+	now := time.Now()
+	sil := &types.Silence{
+		Matchers: []*types.Matcher{{Name: "alertname", Value: "Test"}},
+		StartsAt: now,
+		EndsAt:   now.Add(time.Hour),
+	}
+	id, err := s.Set(sil)
+	if err != nil {
+		t.Fatalf("Set: %s", err)
+	}
+	if ev := recvSilenceEvent(t, ch); ev.Action != ActionCreate {
+		t.Fatalf("expected ActionCreate, got %v", ev.Action)
+	}
+
+	// Set stores whatever pointer it's given, so a second Set must pass a
+	// distinct object (as a real caller updating a silence would) rather
+	// than mutating sil in place, or the "previous" entry in the merge diff
+	// would alias the new one and never look different.
+	updated := *sil
+	updated.ID = id
+	updated.Comment = "updated"
+	if _, err := s.Set(&updated); err != nil {
+		t.Fatalf("Set (update): %s", err)
+	}
+
+	ev := recvSilenceEvent(t, ch)
+	if ev.Action != ActionUpdate {
+		t.Errorf("expected ActionUpdate, got %v", ev.Action)
+	}
+	if ev.PrevUpdatedAt.IsZero() {
+		t.Error("expected PrevUpdatedAt to be set for an update")
+	}
+}
+
+func TestSilencesSubscribeDelete(t *testing.T) {
+	s := NewSilences(nil, nil)
+	s.send = noopTransport{}
+
+	ch, cancel := s.Subscribe()
+	defer cancel()
+
+	now := time.Now()
+	sil := &types.Silence{
+		Matchers: []*types.Matcher{{Name: "alertname", Value: "Test"}},
+		StartsAt: now,
+		EndsAt:   now.Add(time.Hour),
+	}
+	id, err := s.Set(sil)
+	if err != nil {
+		t.Fatalf("Set: %s", err)
+	}
+	if ev := recvSilenceEvent(t, ch); ev.Action != ActionCreate {
+		t.Fatalf("expected ActionCreate, got %v", ev.Action)
+	}
+
+	if err := s.Del(id); err != nil {
+		t.Fatalf("Del: %s", err)
+	}
+
+	if ev := recvSilenceEvent(t, ch); ev.Action != ActionDelete {
+		t.Errorf("expected ActionDelete, got %v", ev.Action)
+	}
+}
+
+func TestSilencesSubscribeSync(t *testing.T) {
+	s := NewSilences(nil, nil)
+	s.send = noopTransport{}
+
+	ch, cancel := s.Subscribe()
+	defer cancel()
+
+	now := time.Now()
+	remote := newSilenceState()
+	sil := &types.Silence{
+		ID:        uuid.NewV4(),
+		Matchers:  []*types.Matcher{{Name: "alertname", Value: "Test"}},
+		StartsAt:  now,
+		EndsAt:    now.Add(time.Hour),
+		UpdatedAt: now,
+	}
+	remote.set[sil.ID] = sil
+
+	if err := s.OnGossipUnicast("peer", remote.Encode()[0]); err != nil {
+		t.Fatalf("OnGossipUnicast: %s", err)
+	}
+
+	if ev := recvSilenceEvent(t, ch); ev.Action != ActionSync {
+		t.Errorf("expected ActionSync, got %v", ev.Action)
+	}
+}
+
+// TestSilencesSubscribeDropsOldestWhenSlow exercises the documented
+// drop-oldest policy: a subscriber that never drains its channel must not
+// block publish, and once its buffer is full the oldest buffered event is
+// discarded to make room for the newest one.
+func TestSilencesSubscribeDropsOldestWhenSlow(t *testing.T) {
+	s := NewSilences(nil, nil)
+
+	ch, cancel := s.Subscribe()
+	defer cancel()
+
+	total := subscriberBufferSize + 10
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < total; i++ {
+			s.subs.publish(SilenceEvent{
+				Action:  ActionSync,
+				Silence: &types.Silence{Comment: fmt.Sprintf("%d", i)},
+			})
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("publish blocked instead of dropping the oldest buffered event")
+	}
+
+	if l := len(ch); l != subscriberBufferSize {
+		t.Fatalf("expected the channel to be full at %d, got %d", subscriberBufferSize, l)
+	}
+
+	first := <-ch
+	if first.Silence.Comment == "0" {
+		t.Error("expected the oldest buffered event to have been dropped")
+	}
+
+	var last SilenceEvent
+	for {
+		select {
+		case ev := <-ch:
+			last = ev
+			continue
+		default:
+		}
+		break
+	}
+	if want := fmt.Sprintf("%d", total-1); last.Silence.Comment != want {
+		t.Errorf("expected the most recent event (%s) to survive, got %s", want, last.Silence.Comment)
+	}
+}
+
+func TestNotificationInfosSubscribe(t *testing.T) {
+	ni := NewNotificationInfos(nil)
+	ni.send = noopTransport{}
+
+	ch, cancel := ni.Subscribe()
+	defer cancel()
+
+	now := time.Now()
+	if err := ni.Set(&types.NotifyInfo{Alert: model.Fingerprint(1), Receiver: "default", Timestamp: now}); err != nil {
+		t.Fatalf("Set: %s", err)
+	}
+
+	if ev := recvNotificationEvent(t, ch); ev.Action != ActionCreate {
+		t.Errorf("expected ActionCreate, got %v", ev.Action)
+	}
+}
+
+func TestNotificationInfosSubscribeUpdate(t *testing.T) {
+	ni := NewNotificationInfos(nil)
+	ni.send = noopTransport{}
+
+	ch, cancel := ni.Subscribe()
+	defer cancel()
+
+	now := time.Now()
+	if err := ni.Set(&types.NotifyInfo{Alert: model.Fingerprint(1), Receiver: "default", Timestamp: now}); err != nil {
+		t.Fatalf("Set: %s", err)
+	}
+	if ev := recvNotificationEvent(t, ch); ev.Action != ActionCreate {
+		t.Fatalf("expected ActionCreate, got %v", ev.Action)
+	}
+
+	if err := ni.Set(&types.NotifyInfo{Alert: model.Fingerprint(1), Receiver: "default", Resolved: true, Timestamp: now.Add(time.Minute)}); err != nil {
+		t.Fatalf("Set (update): %s", err)
+	}
+
+	ev := recvNotificationEvent(t, ch)
+	if ev.Action != ActionUpdate {
+		t.Errorf("expected ActionUpdate, got %v", ev.Action)
+	}
+	if ev.PrevUpdatedAt.IsZero() {
+		t.Error("expected PrevUpdatedAt to be set for an update")
+	}
+}
+
+func TestNotificationInfosSubscribeSync(t *testing.T) {
+	ni := NewNotificationInfos(nil)
+	ni.send = noopTransport{}
+
+	ch, cancel := ni.Subscribe()
+	defer cancel()
+
+	now := time.Now()
+	remote := newNotificationState()
+	remote.set[notificationKey(model.Fingerprint(7), "default")] = notificationEntry{
+		Alert:     model.Fingerprint(7),
+		Receiver:  "default",
+		Timestamp: now,
+	}
+
+	if err := ni.OnGossipUnicast("peer", remote.Encode()[0]); err != nil {
+		t.Fatalf("OnGossipUnicast: %s", err)
+	}
+
+	if ev := recvNotificationEvent(t, ch); ev.Action != ActionSync {
+		t.Errorf("expected ActionSync, got %v", ev.Action)
+	}
+}
+
+type noopTransport struct{}
+
+func (noopTransport) Broadcast(GossipData)         {}
+func (noopTransport) Unicast(string, []byte) error { return nil }
+func (noopTransport) RegisterReceiver(Gossiper)    {}
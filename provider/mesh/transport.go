@@ -0,0 +1,37 @@
+package mesh
+
+// Gossiper is implemented by the types that hold cluster state and want to
+// participate in gossip, e.g. NotificationInfos and Silences. It mirrors
+// weaveworks/mesh.Gossiper so existing merge logic can be reused verbatim,
+// but keeps the rest of the package independent of any particular transport.
+type Gossiper interface {
+	// Gossip returns the current state to be sent to peers that join or
+	// periodically resync.
+	Gossip() GossipData
+	// OnGossip merges received data into the local state and returns
+	// only the changes caused by the merge, or nil if nothing changed.
+	OnGossip(buf []byte) (GossipData, error)
+	// OnGossipBroadcast merges broadcast data received from src into the
+	// local state and returns the resulting delta to be re-broadcast.
+	OnGossipBroadcast(src string, buf []byte) (GossipData, error)
+	// OnGossipUnicast merges data addressed directly to us from src.
+	OnGossipUnicast(src string, buf []byte) error
+}
+
+// Transport abstracts the cluster membership and gossip mechanism used to
+// propagate silences and notification log entries between Alertmanager
+// peers. Implementations exist for weaveworks/mesh (NewMeshTransport) and
+// for hashicorp/memberlist (NewMemberlistTransport); this package defines no
+// flag to choose between them, the caller constructs the one it wants and
+// passes it to Register. Decoupling Gossiper from the transport lets NotificationInfos and
+// Silences stay oblivious to which one is in use.
+type Transport interface {
+	// Broadcast enqueues data to be broadcast to all known peers.
+	Broadcast(GossipData)
+	// Unicast sends b directly to the named peer.
+	Unicast(peer string, b []byte) error
+	// RegisterReceiver wires up a Gossiper to receive broadcasts and
+	// unicasts from the transport, and to supply the state the transport
+	// should hand to peers that join or resync.
+	RegisterReceiver(Gossiper)
+}
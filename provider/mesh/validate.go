@@ -0,0 +1,102 @@
+package mesh
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/log"
+	"github.com/satori/go.uuid"
+)
+
+// minValidSeconds and maxValidSeconds are the Unix-seconds bounds of
+// google.protobuf.Timestamp (years 0001-01-01 through 9999-12-31), the
+// range ptypes.TimestampProto accepts. mustTimestamp (encode.go) panics on
+// a time.Time outside this range, so validateSilence rejects one here
+// rather than letting it reach Gossip/Broadcast.
+const (
+	minValidSeconds = -62135596800
+	maxValidSeconds = 253402300799
+)
+
+func validTimestamp(t time.Time) bool {
+	s := t.Unix()
+	return s >= minValidSeconds && s <= maxValidSeconds
+}
+
+// ErrInvalidSilence is returned by validateSilence when a silence fails
+// validation, either because it was submitted locally or because it was
+// received from a peer via gossip. Callers that expose silences over HTTP
+// can use it to render a 400 instead of a 500.
+type ErrInvalidSilence struct {
+	Field  string
+	Reason string
+}
+
+func (e *ErrInvalidSilence) Error() string {
+	return fmt.Sprintf("invalid silence: %s: %s", e.Field, e.Reason)
+}
+
+// validateSilence rejects malformed silences before they are merged into
+// local state or gossiped to the rest of the cluster, so that a single
+// buggy or malicious peer cannot corrupt everyone else's state.
+func validateSilence(sil *types.Silence) error {
+	if sil.ID == uuid.Nil {
+		return &ErrInvalidSilence{Field: "id", Reason: "missing ID"}
+	}
+	if len(sil.Matchers) == 0 {
+		return &ErrInvalidSilence{Field: "matchers", Reason: "at least one matcher is required"}
+	}
+	for _, m := range sil.Matchers {
+		if m.Name == "" {
+			return &ErrInvalidSilence{Field: "matchers", Reason: "matcher name must not be empty"}
+		}
+		if m.IsRegex {
+			if _, err := regexp.Compile(m.Value); err != nil {
+				return &ErrInvalidSilence{Field: "matchers", Reason: fmt.Sprintf("invalid regex %q: %s", m.Value, err)}
+			}
+		}
+	}
+	if sil.StartsAt.IsZero() {
+		return &ErrInvalidSilence{Field: "startsAt", Reason: "must be set"}
+	}
+	if !validTimestamp(sil.StartsAt) {
+		return &ErrInvalidSilence{Field: "startsAt", Reason: "outside the representable timestamp range"}
+	}
+	if sil.EndsAt.IsZero() {
+		return &ErrInvalidSilence{Field: "endsAt", Reason: "must be set"}
+	}
+	if !validTimestamp(sil.EndsAt) {
+		return &ErrInvalidSilence{Field: "endsAt", Reason: "outside the representable timestamp range"}
+	}
+	if !sil.EndsAt.After(sil.StartsAt) {
+		return &ErrInvalidSilence{Field: "endsAt", Reason: "must be after startsAt"}
+	}
+	if sil.UpdatedAt.IsZero() {
+		return &ErrInvalidSilence{Field: "updatedAt", Reason: "must be set"}
+	}
+	if !validTimestamp(sil.UpdatedAt) {
+		return &ErrInvalidSilence{Field: "updatedAt", Reason: "outside the representable timestamp range"}
+	}
+	return nil
+}
+
+// validateSilenceState drops every silence in st that fails validateSilence,
+// logging each one removed. It is used to guard remote state merged in via
+// gossip: OnGossipUnicast carries a peer's entire state on join or resync,
+// so rejecting the whole batch over a single bad entry (e.g. a legacy
+// record, or one introduced by a bug elsewhere) would permanently block
+// that peer from ever completing a full-state resync again. Filtering the
+// bad entries out instead keeps the rest of the state mergeable.
+func validateSilenceState(st *silenceState, logger log.Logger) {
+	st.mtx.Lock()
+	defer st.mtx.Unlock()
+
+	for id, sil := range st.set {
+		if err := validateSilence(sil); err != nil {
+			logger.With("silence", id).With("err", err).Warn("dropping invalid silence received from peer")
+			delete(st.set, id)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package mesh
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/types"
+	"github.com/satori/go.uuid"
+)
+
+func TestValidateSilence(t *testing.T) {
+	now := time.Now()
+
+	base := func() *types.Silence {
+		return &types.Silence{
+			ID: uuid.NewV4(),
+			Matchers: []*types.Matcher{
+				{Name: "alertname", Value: "Test"},
+			},
+			StartsAt:  now,
+			EndsAt:    now.Add(time.Hour),
+			UpdatedAt: now,
+		}
+	}
+
+	if err := validateSilence(base()); err != nil {
+		t.Fatalf("expected valid silence to pass, got: %s", err)
+	}
+
+	cases := []struct {
+		name string
+		mod  func(*types.Silence)
+	}{
+		{"no id", func(s *types.Silence) { s.ID = uuid.Nil }},
+		{"no matchers", func(s *types.Silence) { s.Matchers = nil }},
+		{"empty matcher name", func(s *types.Silence) { s.Matchers[0].Name = "" }},
+		{"invalid regex", func(s *types.Silence) { s.Matchers[0].IsRegex = true; s.Matchers[0].Value = "[" }},
+		{"ends before starts", func(s *types.Silence) { s.EndsAt = s.StartsAt.Add(-time.Minute) }},
+		{"zero updatedAt", func(s *types.Silence) { s.UpdatedAt = time.Time{} }},
+		{"startsAt out of range", func(s *types.Silence) { s.StartsAt = time.Date(99999, 1, 1, 0, 0, 0, 0, time.UTC) }},
+		{"endsAt out of range", func(s *types.Silence) { s.EndsAt = time.Date(99999, 1, 1, 0, 0, 0, 0, time.UTC) }},
+		{"updatedAt out of range", func(s *types.Silence) { s.UpdatedAt = time.Date(99999, 1, 1, 0, 0, 0, 0, time.UTC) }},
+	}
+	for _, c := range cases {
+		sil := base()
+		c.mod(sil)
+		if err := validateSilence(sil); err == nil {
+			t.Errorf("%s: expected error, got none", c.name)
+		}
+	}
+}